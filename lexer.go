@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 )
 
@@ -45,26 +46,71 @@ const (
 
 	// Special End of file token
 	EOF tokenType = 13
+
+	// Flow collection tokens (flow indicators, see NimYAML's flowIndicators)
+	LEFT_CURLY_BRACE  tokenType = 14 // For flow mappings ("{")
+	RIGHT_CURLY_BRACE tokenType = 15 // For flow mappings ("}")
+	COMMA             tokenType = 16 // For separating flow collection entries
+
+	// Anchor and alias tokens
+	ANCHOR tokenType = 17 // For anchor declarations ("&name")
+	ALIAS  tokenType = 18 // For alias references ("*name")
+
+	// Block scalar header, e.g. "|", ">", "|-", ">2+"
+	BLOCK_SCALAR_HEADER tokenType = 19
+
+	// Document stream markers, only recognized at the start of a line
+	DOC_START tokenType = 20 // "---", begins a new document
+	DOC_END   tokenType = 21 // "...", ends a document
 )
 
+// isFlowIndicator reports whether ch is one of the YAML flow indicators.
+func isFlowIndicator(ch byte) bool {
+	switch ch {
+	case '[', ']', '{', '}', ',':
+		return true
+	default:
+		return false
+	}
+}
+
 // Token containing the value and type of the token, and current pos in the
 // input
 type Token struct {
 	Value string    // Value of the token
 	Type  tokenType // The type of the token
-	Pos   int       // Position of the token
+	Pos   Pos       // Position of the token
 }
 
 func (t Token) String() string {
-	return fmt.Sprintf("Token{Value: %q, Type: %q, Pos: %d}", t.Value, t.Type.String(), t.Pos)
+	return fmt.Sprintf("Token{Value: %q, Type: %q, Pos: %s}", t.Value, t.Type.String(), t.Pos)
 }
 
 // Lexer will read the input and breaks it into tokens
 // It will shift from left to right, keeping track of characters
 // and move its pos accordingly
 type Lexer struct {
-	input []byte
-	pos   int
+	input    []byte
+	pos      int
+	filename string // name stamped on every Token's Pos, if any
+
+	line, col int // 1-indexed position of the next unread byte
+
+	// tokenStart is the Pos of the char NextToken is about to consume,
+	// i.e. the start position of the token being produced
+	tokenStart Pos
+
+	// flowDepth counts nested "[...]"/"{...}" flow collections we're
+	// currently inside. Flow indicators ([]{},) only terminate a plain
+	// scalar while this is > 0; outside of a flow collection they're
+	// ordinary content (e.g. "desc: hello, world").
+	flowDepth int
+}
+
+// inFlowContext reports whether ch is a flow indicator that should terminate
+// the scalar being read, which is only the case inside a flow collection.
+func (l *Lexer) inFlowContext(ch byte) bool {
+	return l.flowDepth > 0 && isFlowIndicator(ch)
 }
 
 // nextChar will read the next character from the input, return it
@@ -76,6 +122,12 @@ func (l *Lexer) nextChar() byte {
 	}
 	ch := l.input[l.pos]
 	l.pos++
+	if ch == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
 	return ch
 }
 
@@ -92,18 +144,46 @@ func (l *Lexer) peekChar() byte {
 
 func (l *Lexer) NextToken() Token {
 	for {
+		l.tokenStart = Pos{Filename: l.filename, Line: l.line, Column: l.col}
+
+		if l.col == 1 {
+			if tok, ok := l.tryDocMarker(); ok {
+				return tok
+			}
+		}
+
 		currentChar := l.nextChar()
 		switch currentChar {
 		case '\'', '"':
 			return l.readQuotedString()
 		case ':':
-			return Token{Type: COLON, Pos: l.pos - 1}
+			return Token{Type: COLON, Pos: l.tokenStart}
 		case '[':
-			return Token{Type: LEFT_SQUARE_BRACKET, Pos: l.pos - 1}
+			l.flowDepth++
+			return Token{Type: LEFT_SQUARE_BRACKET, Pos: l.tokenStart}
 		case ']':
-			return Token{Type: RIGHT_SQUARE_BRACKET, Pos: l.pos - 1}
+			if l.flowDepth > 0 {
+				l.flowDepth--
+			}
+			return Token{Type: RIGHT_SQUARE_BRACKET, Pos: l.tokenStart}
+		case '{':
+			l.flowDepth++
+			return Token{Type: LEFT_CURLY_BRACE, Pos: l.tokenStart}
+		case '}':
+			if l.flowDepth > 0 {
+				l.flowDepth--
+			}
+			return Token{Type: RIGHT_CURLY_BRACE, Pos: l.tokenStart}
+		case ',':
+			return Token{Type: COMMA, Pos: l.tokenStart}
+		case '&':
+			return l.readAnchor()
+		case '*':
+			return l.readAlias()
+		case '|', '>':
+			return l.readBlockScalarHeader()
 		case '-':
-			return Token{Type: HYPHEN, Pos: l.pos - 1}
+			return Token{Type: HYPHEN, Pos: l.tokenStart}
 		case '#':
 			return l.readComment()
 		case 't', 'f':
@@ -113,16 +193,16 @@ func (l *Lexer) NextToken() Token {
 		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
 			return l.readNumber()
 		case '\n':
-			return Token{Type: NEWLINE, Pos: l.pos - 1}
+			return Token{Type: NEWLINE, Pos: l.tokenStart}
 		case '\t':
-			return Token{Type: INVALID, Pos: l.pos - 1, Value: "tabs are not valid within yaml files"}
+			return Token{Type: INVALID, Pos: l.tokenStart, Value: "tabs are not valid within yaml files"}
 		case 0:
-			return Token{Type: EOF, Pos: l.pos - 1}
+			return Token{Type: EOF, Pos: l.tokenStart}
 		// by default just handle everything as unquoted string
 		// except for space chars
 		default:
 			if unicode.IsSpace(rune(currentChar)) {
-				return Token{Type: SPACE, Pos: l.pos}
+				return Token{Type: SPACE, Pos: l.tokenStart}
 			}
 			return l.readUnquotedString()
 		}
@@ -141,12 +221,36 @@ func (l *Lexer) readQuotedString() Token {
 		if ch == quoteChar || ch == 0 {
 			break
 		}
+		if quoteChar == '"' && ch == '\\' {
+			l.nextChar() // skip the escaped char so a literal \" doesn't end the string early
+		}
 	}
 
 	if l.input[l.pos-1] != quoteChar {
-		return Token{Type: INVALID, Pos: start, Value: "unterminated string"}
+		return Token{Type: INVALID, Pos: l.tokenStart, Value: "unterminated string"}
+	}
+
+	value, err := unquoteString(l.input[start:l.pos])
+	if err != nil {
+		return Token{Type: INVALID, Pos: l.tokenStart, Value: err.Error()}
 	}
-	return Token{Type: STRING, Value: string(l.input[start:l.pos]), Pos: start}
+	return Token{Type: STRING, Value: value, Pos: l.tokenStart}
+}
+
+// unquoteString strips the surrounding quote characters from a quoted
+// scalar and interprets its escapes. Double-quoted strings use Go/YAML-style
+// backslash escapes, handled via strconv.Unquote; single-quoted strings only
+// escape a literal quote, written as two consecutive single quotes.
+func unquoteString(raw []byte) (string, error) {
+	if len(raw) < 2 {
+		return string(raw), nil
+	}
+	quoteChar := raw[0]
+	inner := raw[1 : len(raw)-1]
+	if quoteChar == '"' {
+		return strconv.Unquote(`"` + string(inner) + `"`)
+	}
+	return strings.ReplaceAll(string(inner), "''", "'"), nil
 }
 
 func (l *Lexer) readUnquotedString() Token {
@@ -154,23 +258,25 @@ func (l *Lexer) readUnquotedString() Token {
 
 	// read till the end of file or till the new line char, or we get comment
 	for {
+		prevLine, prevCol := l.line, l.col
 		ch := l.nextChar()
 		if ch == 0 || l.isStartOfInlineComment() {
 			break
 		}
-		if l.isStartOfColon() || ch == '\n' {
+		if l.isStartOfColon() || ch == '\n' || l.inFlowContext(ch) {
 			l.pos--
+			l.line, l.col = prevLine, prevCol
 			break
 		}
 	}
 	// now remove all the trailing white spaces
 	end := l.pos - 1
 	if !unicode.IsSpace(rune(l.input[end])) {
-		return Token{Type: STRING, Pos: start, Value: string(l.input[start : end+1])}
+		return Token{Type: STRING, Pos: l.tokenStart, Value: string(l.input[start : end+1])}
 	}
 	for ; end >= 0 && !unicode.IsSpace(rune(l.input[end])); end-- {
 	}
-	return Token{Type: STRING, Pos: start, Value: string(l.input[start : end+1])}
+	return Token{Type: STRING, Pos: l.tokenStart, Value: string(l.input[start : end+1])}
 }
 
 // readNumber will try to read the number from the current position
@@ -190,7 +296,7 @@ func (l *Lexer) readNumber() Token {
 		}
 
 		// check for the end of the line or end of file or end of object, or we get any comment
-		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() {
+		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() || l.inFlowContext(ch) {
 			break
 		}
 		l.nextChar()
@@ -203,9 +309,10 @@ func (l *Lexer) readNumber() Token {
 	_, err := strconv.ParseFloat(numStr, 64)
 	if err != nil {
 		l.pos = start + 1
+		l.col = l.tokenStart.Column + 1
 		return l.readUnquotedString()
 	}
-	return Token{Type: numType, Value: numStr, Pos: start}
+	return Token{Type: numType, Value: numStr, Pos: l.tokenStart}
 }
 
 // readBoolean will read through the input bytes and try to parse the booleans
@@ -215,7 +322,7 @@ func (l *Lexer) readBoolean() Token {
 	for {
 		ch := l.peekChar()
 		// check for the end of the line or end of file or end of object, or we get comment
-		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() {
+		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() || l.inFlowContext(ch) {
 			break
 		}
 		l.nextChar()
@@ -224,9 +331,10 @@ func (l *Lexer) readBoolean() Token {
 
 	if !bytes.Equal(boolByte, trueByte) && !bytes.Equal(boolByte, falseByte) {
 		l.pos = start + 1
+		l.col = l.tokenStart.Column + 1
 		return l.readUnquotedString()
 	}
-	return Token{Type: BOOLEAN, Value: string(boolByte), Pos: start}
+	return Token{Type: BOOLEAN, Value: string(boolByte), Pos: l.tokenStart}
 }
 
 // readNull will read through the input bytes and try to parse the null
@@ -236,7 +344,7 @@ func (l *Lexer) readNull() Token {
 	for {
 		ch := l.peekChar()
 		// check for the end of the line or end of file, or we get comment
-		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() || l.isStartOfColon() {
+		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() || l.isStartOfColon() || l.inFlowContext(ch) {
 			break
 		}
 		l.nextChar()
@@ -244,15 +352,15 @@ func (l *Lexer) readNull() Token {
 	found := l.input[start:l.pos]
 	if !bytes.Equal(found, nullByte) {
 		l.pos = start + 1
+		l.col = l.tokenStart.Column + 1
 		return l.readUnquotedString()
 	}
-	return Token{Type: NULL, Value: string(found), Pos: start}
+	return Token{Type: NULL, Value: string(found), Pos: l.tokenStart}
 }
 
 // readComment will just move our lexer to new line, so we can skip the
 // commented section
 func (l *Lexer) readComment() Token {
-	start := l.pos - 1
 	// read till the end
 	for {
 		ch := l.peekChar()
@@ -262,7 +370,129 @@ func (l *Lexer) readComment() Token {
 		}
 		l.nextChar()
 	}
-	return Token{Type: COMMENT, Pos: start}
+	return Token{Type: COMMENT, Pos: l.tokenStart}
+}
+
+// readBlockScalarHeader reads a literal ("|") or folded (">") block scalar
+// header, along with an optional chomping indicator ("-"/"+") and explicit
+// indentation digit, e.g. "|-2" or ">+".
+func (l *Lexer) readBlockScalarHeader() Token {
+	start := l.pos - 1
+	for {
+		ch := l.peekChar()
+		if ch == '-' || ch == '+' || (ch >= '0' && ch <= '9') {
+			l.nextChar()
+			continue
+		}
+		break
+	}
+	return Token{Type: BLOCK_SCALAR_HEADER, Value: string(l.input[start:l.pos]), Pos: l.tokenStart}
+}
+
+// readBlockBody reads the body of a literal/folded block scalar, which
+// starts on the line following its BLOCK_SCALAR_HEADER token. explicitIndent
+// is the indentation width taken from the header's digit, or 0 to detect it
+// from the first non-empty line. It returns the body's lines with the
+// block's leading indentation stripped (blank lines are returned as "").
+// A line indented less than the block stops the body; the lexer is left
+// positioned at the start of that line so normal tokenizing can resume.
+func (l *Lexer) readBlockBody(explicitIndent int) []string {
+	// skip past anything left on the header line (e.g. a trailing comment)
+	for l.peekChar() != 0 && l.peekChar() != '\n' {
+		l.nextChar()
+	}
+	if l.peekChar() == '\n' {
+		l.nextChar() // consume the newline
+	}
+
+	indent := explicitIndent
+	var lines []string
+	for l.peekChar() != 0 {
+		lineStart, lineStartLine, lineStartCol := l.pos, l.line, l.col
+		for l.peekChar() != 0 && l.peekChar() != '\n' {
+			l.nextChar()
+		}
+		line := string(l.input[lineStart:l.pos])
+		if l.peekChar() == '\n' {
+			l.nextChar() // consume the newline
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		lineIndent := len(line) - len(trimmed)
+		if indent == 0 {
+			indent = lineIndent
+		}
+		if lineIndent < indent {
+			// this line belongs to what follows the block scalar
+			l.pos, l.line, l.col = lineStart, lineStartLine, lineStartCol
+			break
+		}
+
+		lines = append(lines, line[indent:])
+	}
+
+	return lines
+}
+
+// tryDocMarker recognizes "---" (DOC_START) and "..." (DOC_END) when they
+// appear at the start of a line followed by end-of-line, whitespace, or
+// end-of-input, matching YAML's directives-end/document-end markers.
+func (l *Lexer) tryDocMarker() (Token, bool) {
+	if l.pos+3 > len(l.input) {
+		return Token{}, false
+	}
+	marker := string(l.input[l.pos : l.pos+3])
+	if marker != "---" && marker != "..." {
+		return Token{}, false
+	}
+
+	var next byte
+	if l.pos+3 < len(l.input) {
+		next = l.input[l.pos+3]
+	}
+	if next != 0 && next != '\n' && !unicode.IsSpace(rune(next)) {
+		return Token{}, false
+	}
+
+	for i := 0; i < 3; i++ {
+		l.nextChar()
+	}
+	if marker == "---" {
+		return Token{Type: DOC_START, Pos: l.tokenStart}, true
+	}
+	return Token{Type: DOC_END, Pos: l.tokenStart}, true
+}
+
+// readAnchor will read the name following an '&' anchor marker
+func (l *Lexer) readAnchor() Token {
+	name := l.readAnchorOrAliasName()
+	return Token{Type: ANCHOR, Value: name, Pos: l.tokenStart}
+}
+
+// readAlias will read the name following a '*' alias marker
+func (l *Lexer) readAlias() Token {
+	name := l.readAnchorOrAliasName()
+	return Token{Type: ALIAS, Value: name, Pos: l.tokenStart}
+}
+
+// readAnchorOrAliasName reads the name following an anchor/alias marker,
+// stopping at the end of the line, a comment, a flow indicator, or any
+// whitespace
+func (l *Lexer) readAnchorOrAliasName() string {
+	start := l.pos
+	for {
+		ch := l.peekChar()
+		if ch == 0 || ch == '\n' || l.isStartOfInlineComment() || l.inFlowContext(ch) || unicode.IsSpace(rune(ch)) {
+			break
+		}
+		l.nextChar()
+	}
+	return string(l.input[start:l.pos])
 }
 
 // isStartOfInlineComment will check for the start of an inline comment
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+// TestLexerPositionsAcrossLines checks that line/column tracking survives a
+// readUnquotedString backtrack (e.g. on a newline), which previously left
+// the lexer's internal line counter off by one for every such value.
+func TestLexerPositionsAcrossLines(t *testing.T) {
+	lex := Lexer{input: []byte("name: web\nversion: 2\n"), line: 1, col: 1}
+
+	type want struct {
+		typ  tokenType
+		line int
+		col  int
+	}
+	wantTokens := []want{
+		{STRING, 1, 1},      // name
+		{COLON, 1, 5},       // :
+		{SPACE, 1, 6},       // " "
+		{STRING, 1, 7},      // web
+		{NEWLINE, 1, 10},    // "\n"
+		{STRING, 2, 1},      // version
+		{COLON, 2, 8},       // :
+		{SPACE, 2, 9},       // " "
+		{INT_NUMBER, 2, 10}, // 2
+		{NEWLINE, 2, 11},
+		{EOF, 3, 1},
+	}
+
+	for i, w := range wantTokens {
+		tok := lex.NextToken()
+		if tok.Type != w.typ {
+			t.Fatalf("token %d: expected type %s, got %s (%q)", i, w.typ, tok.Type, tok.Value)
+		}
+		if tok.Pos.Line != w.line || tok.Pos.Column != w.col {
+			t.Errorf("token %d (%s): expected pos %d:%d, got %d:%d", i, tok.Type, w.line, w.col, tok.Pos.Line, tok.Pos.Column)
+		}
+	}
+}
+
+// TestPlainScalarFlowIndicatorsAreContextSensitive checks that characters
+// like ',' and '{' only terminate a plain scalar while inside a flow
+// collection, not wherever they appear in block-style content (e.g. prose).
+func TestPlainScalarFlowIndicatorsAreContextSensitive(t *testing.T) {
+	lex := Lexer{input: []byte("desc: hello, world\n")}
+
+	var got []Token
+	for {
+		tok := lex.NextToken()
+		got = append(got, tok)
+		if tok.Type == EOF {
+			break
+		}
+	}
+
+	wantTypes := []tokenType{STRING, COLON, SPACE, STRING, NEWLINE, EOF}
+	if len(got) != len(wantTypes) {
+		t.Fatalf("expected %d tokens, got %d: %v", len(wantTypes), len(got), got)
+	}
+	for i, typ := range wantTypes {
+		if got[i].Type != typ {
+			t.Fatalf("token %d: expected type %s, got %s (%q)", i, typ, got[i].Type, got[i].Value)
+		}
+	}
+	if got[3].Value != "hello, world" {
+		t.Errorf("expected the comma to stay part of the plain scalar, got %q", got[3].Value)
+	}
+}
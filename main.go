@@ -23,7 +23,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	parser := NewParser(fileContent)
+	parser := NewParserWithFilename(fileContent, os.Args[1])
 	yaml, err := parser.Parse()
 	if err != nil {
 		fmt.Println(err)
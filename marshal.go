@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marshal encodes v as yaml, via reflection. v must be (a pointer to) a
+// struct or a map with string keys once it reaches the top level. Nested
+// structs, maps, and slices are rendered as flow collections (`{...}`,
+// `[...]`), so the result is always a single block-style mapping with one
+// line per top-level field.
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: cannot marshal a nil %s", ErrUnsupportedType, rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	var b strings.Builder
+	switch rv.Kind() {
+	case reflect.Struct:
+		if err := marshalTopLevelStruct(rv, &b); err != nil {
+			return nil, err
+		}
+	case reflect.Map:
+		if err := marshalTopLevelMap(rv, &b); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: Marshal requires a struct or map at the top level, got %s", ErrUnsupportedType, rv.Type())
+	}
+	return []byte(b.String()), nil
+}
+
+func marshalTopLevelStruct(rv reflect.Value, b *strings.Builder) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitempty, skip := yamlFieldName(field)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		val, err := encodeInline(fv)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s: %s\n", name, val)
+	}
+	return nil
+}
+
+func marshalTopLevelMap(rv reflect.Value, b *strings.Builder) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: map key must be a string, got %s", ErrUnsupportedType, rv.Type().Key())
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, key := range keys {
+		val, err := encodeInline(rv.MapIndex(key))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s: %s\n", key.String(), val)
+	}
+	return nil
+}
+
+// encodeInline renders v as a single-line yaml value: a bare scalar, or a
+// flow collection for structs, maps, and slices.
+func encodeInline(rv reflect.Value) (string, error) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "null", nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return encodeFlowStruct(rv)
+	case reflect.Map:
+		return encodeFlowMap(rv)
+	case reflect.Slice, reflect.Array:
+		return encodeFlowSequence(rv)
+	default:
+		return encodeScalar(rv)
+	}
+}
+
+func encodeFlowStruct(rv reflect.Value) (string, error) {
+	t := rv.Type()
+	var parts []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitempty, skip := yamlFieldName(field)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		val, err := encodeInline(fv)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", name, val))
+	}
+	return "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+func encodeFlowMap(rv reflect.Value) (string, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return "", fmt.Errorf("%w: map key must be a string, got %s", ErrUnsupportedType, rv.Type().Key())
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	var parts []string
+	for _, key := range keys {
+		val, err := encodeInline(rv.MapIndex(key))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", key.String(), val))
+	}
+	return "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+func encodeFlowSequence(rv reflect.Value) (string, error) {
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		val, err := encodeInline(rv.Index(i))
+		if err != nil {
+			return "", err
+		}
+		parts[i] = val
+	}
+	return "[" + strings.Join(parts, ", ") + "]", nil
+}
+
+// encodeScalar renders a non-container value as a bare yaml scalar.
+func encodeScalar(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return encodeScalarString(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+}
+
+// encodeScalarString quotes s if leaving it bare would change its meaning
+// (e.g. it looks like a number, a bool, or contains a flow indicator).
+func encodeScalarString(s string) string {
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuoting(s string) bool {
+	switch s {
+	case "", "true", "false", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s, ":,[]{}#&*!|>'\"%@`\n") {
+		return true
+	}
+	return strings.HasPrefix(s, " ") || strings.HasSuffix(s, " ")
+}
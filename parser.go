@@ -3,12 +3,15 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 )
 
 var (
-	ErrDuplicateKey  = errors.New("duplicate key")
-	errEmptyYamlFile = parseError{Message: "empty files are not valid yaml"}
+	ErrDuplicateKey   = errors.New("duplicate key")
+	ErrUndefinedAlias = errors.New("undefined alias")
+	errEmptyYamlFile  = parseError{Message: "empty files are not valid yaml", Pos: InitPos}
 )
 
 // Parser for yaml inputs in byte
@@ -17,6 +20,7 @@ type Parser struct {
 	currToken          Token
 	prevToken          *Token
 	currentIndentation int
+	anchors            map[string]yamlVal // anchors registered so far, keyed by anchor name
 }
 
 func (p Parser) String() string {
@@ -26,14 +30,14 @@ func (p Parser) String() string {
 // parseError the custom error
 type parseError struct {
 	Message string
-	Pos     int
+	Pos     Pos
 }
 
 func (e parseError) Error() string {
-	return fmt.Sprintf("YAML parse error at position %d: %s", e.Pos, e.Message)
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
 }
 
-func newParseError(msg string, pos int) error {
+func newParseError(msg string, pos Pos) error {
 	return parseError{Message: msg, Pos: pos}
 }
 
@@ -41,8 +45,16 @@ var _ error = parseError{}
 
 // NewParser the constructor for the Parser,which initializes the Parser
 func NewParser(input []byte) *Parser {
-	lex := Lexer{input: input}
-	return &Parser{lexer: &lex, currToken: lex.NextToken()}
+	lex := Lexer{input: input, line: 1, col: 1}
+	return &Parser{lexer: &lex, currToken: lex.NextToken(), anchors: make(map[string]yamlVal)}
+}
+
+// NewParserWithFilename is like NewParser, but stamps every token's
+// position with the given filename so error messages can point back to
+// a real file, e.g. "config.yaml:4:2: expected ':' after key declaration"
+func NewParserWithFilename(input []byte, name string) *Parser {
+	lex := Lexer{input: input, filename: name, line: 1, col: 1}
+	return &Parser{lexer: &lex, currToken: lex.NextToken(), anchors: make(map[string]yamlVal)}
 }
 
 // NextToken the helper function to get the next token from the lexer
@@ -68,18 +80,91 @@ func (p *Parser) peekToken() Token {
 }
 
 // getPos the helper function to get the current token's position
-func (p *Parser) getPos() int {
+func (p *Parser) getPos() Pos {
 	return p.currToken.Pos
 }
 
+// Parse parses a single yaml document from the parser's input. It rejects
+// input that contains more than one document: use Next or All to read a
+// multi-document stream.
 func (p *Parser) Parse() (YAMLObj, error) {
+	obj, err := p.Next()
+	if err != nil {
+		return obj, err
+	}
+
+	if p.currToken.Type == DOC_START || p.currToken.Type == DOC_END {
+		return obj, newParseError("unexpected start of a second document, use Next or All to read a stream", p.getPos())
+	}
+
+	return obj, nil
+}
+
+// Next parses and returns the next document in the stream, advancing past
+// its terminating "---" or "..." marker if present. It returns io.EOF once
+// there are no more documents to read.
+func (p *Parser) Next() (YAMLObj, error) {
+	if p.currToken.Type == DOC_END {
+		p.NextToken()
+	}
+	if p.currToken.Type == DOC_START {
+		p.NextToken()
+	}
+	// the "---"/"..." marker line ends with its own newline before the
+	// document content (or the next marker/EOF) begins
+	for p.currToken.Type == NEWLINE {
+		p.NextToken()
+	}
+
+	if p.currToken.Type == EOF {
+		return YAMLObj{}, io.EOF
+	}
+
+	// each document's indentation is relative to its own content, not
+	// whatever the previous document left currentIndentation at; anchors
+	// are also scoped to a single document, so a *name alias must not
+	// resolve to an &name anchor defined in an earlier one
+	p.currentIndentation = 0
+	p.anchors = make(map[string]yamlVal)
+
+	obj, err := p.parseMapping()
+	if err != nil {
+		return obj, err
+	}
+
+	if p.currToken.Type == DOC_END {
+		p.NextToken()
+	}
+
+	return obj, nil
+}
+
+// All reads and returns every document in the stream.
+func (p *Parser) All() ([]YAMLObj, error) {
+	var docs []YAMLObj
+	for {
+		obj, err := p.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return docs, err
+		}
+		docs = append(docs, obj)
+	}
+}
+
+// parseMapping parses a single block mapping, stopping at EOF or a document
+// stream marker ("---"/"..."). It is used both for the top-level document
+// and, recursively, for nested block mappings reached via parseIndentedValue.
+func (p *Parser) parseMapping() (YAMLObj, error) {
 	obj := YAMLObj{keys: make(map[string]struct{})}
 
 	if p.currToken.Type == EOF {
 		return obj, errEmptyYamlFile
 	}
 
-	for p.currToken.Type != EOF {
+	for p.currToken.Type != EOF && p.currToken.Type != DOC_START && p.currToken.Type != DOC_END {
 		if p.currToken.Type != STRING {
 			return obj, newParseError("Expected string for key", p.getPos())
 		}
@@ -109,12 +194,23 @@ func (p *Parser) Parse() (YAMLObj, error) {
 			return obj, newParseError(err.Error(), p.getPos())
 		}
 
-		p.NextToken()
-		if p.currToken.Type != NEWLINE && p.currToken.Type != EOF {
+		// val may have come from a nested parseMapping/parseList that
+		// already stopped at EOF/DOC_START/DOC_END without consuming it;
+		// in that case currToken is the marker itself and must not be
+		// advanced past here, or the marker (and the document boundary
+		// it represents) is silently lost.
+		if p.currToken.Type != EOF && p.currToken.Type != DOC_START && p.currToken.Type != DOC_END {
+			p.NextToken()
+		}
+		switch p.currToken.Type {
+		case NEWLINE:
+			p.NextToken()
+		case EOF, DOC_START, DOC_END:
+			// nothing left to consume; let the caller see the marker
+		default:
 			msg := fmt.Sprintf("Expected new line after parsing values, got: %s", p.currToken.Type.String())
 			return obj, newParseError(msg, p.getPos())
 		}
-		p.NextToken()
 	}
 
 	return obj, nil
@@ -164,11 +260,271 @@ func (p *Parser) parseValue() (yamlVal, error) {
 		return p.handleSpace()
 	case NEWLINE:
 		return p.handleNewLine()
+	case LEFT_SQUARE_BRACKET:
+		return p.parseFlowSequence()
+	case LEFT_CURLY_BRACE:
+		return p.parseFlowMapping()
+	case ANCHOR:
+		return p.parseAnchor()
+	case ALIAS:
+		return p.parseAlias()
+	case BLOCK_SCALAR_HEADER:
+		return p.parseBlockScalar()
 	default:
 		return nil, newParseError("Expected value", p.getPos())
 	}
 }
 
+// parseBlockScalar parses the body of a literal ("|") or folded (">")
+// block scalar following a BLOCK_SCALAR_HEADER token. The lexer reads the
+// raw indented lines directly, since their content (newlines, indentation)
+// is significant in a way the normal token stream doesn't preserve.
+func (p *Parser) parseBlockScalar() (yamlVal, error) {
+	header := p.currToken.Value
+	style := header[0]
+
+	var chomp byte
+	indent := 0
+	for i := 1; i < len(header); i++ {
+		switch {
+		case header[i] == '-' || header[i] == '+':
+			chomp = header[i]
+		case header[i] >= '0' && header[i] <= '9':
+			indent = int(header[i] - '0')
+		}
+	}
+
+	lines := p.lexer.readBlockBody(indent)
+	body := buildBlockScalar(style, chomp, lines)
+
+	// The lexer has already consumed the block's raw text, including the
+	// newline terminating its last line, so there is no NEWLINE token left
+	// for the caller's next NextToken call to find. Queue a synthetic one
+	// as the peeked token (the same mechanism peekToken uses), and leave
+	// currToken at the BLOCK_SCALAR_HEADER, matching the convention that
+	// parseValue leaves the value's own token unconsumed.
+	newline := Token{Type: NEWLINE, Pos: Pos{Filename: p.lexer.filename, Line: p.lexer.line, Column: p.lexer.col}}
+	p.prevToken = &newline
+
+	return yamlString(body), nil
+}
+
+// buildBlockScalar joins a block scalar's stripped lines into its final
+// string, applying the literal/folded join rule and the chomping indicator.
+func buildBlockScalar(style byte, chomp byte, lines []string) string {
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	content := lines[:end]
+	trailingBlanks := len(lines) - end
+
+	var body string
+	if style == '>' {
+		body = foldBlockLines(content)
+	} else {
+		body = strings.Join(content, "\n")
+	}
+
+	switch chomp {
+	case '-': // strip: no trailing newline at all
+	case '+': // keep: every trailing newline
+		if len(content) > 0 {
+			body += "\n"
+		}
+		body += strings.Repeat("\n", trailingBlanks)
+	default: // clip: exactly one trailing newline
+		if len(content) > 0 {
+			body += "\n"
+		}
+	}
+	return body
+}
+
+// foldBlockLines implements the folded ("> ") join rule: a single newline
+// between two non-blank lines becomes a space, while each blank line
+// contributes exactly one newline to the result (so that a run of k blank
+// lines folds to k newlines, not k+1).
+func foldBlockLines(lines []string) string {
+	var b strings.Builder
+	prevBlank := true // no separator before the first line
+	for _, line := range lines {
+		if line == "" {
+			b.WriteByte('\n')
+			prevBlank = true
+			continue
+		}
+		if b.Len() > 0 && !prevBlank {
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+		prevBlank = false
+	}
+	return b.String()
+}
+
+// parseAnchor parses the value following an `&name` anchor marker and
+// registers it under name so a later `*name` alias can look it up.
+func (p *Parser) parseAnchor() (yamlVal, error) {
+	name := p.currToken.Value
+	p.NextToken()
+	if p.currToken.Type == SPACE {
+		p.NextToken()
+	}
+
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.anchors[name] = val
+	return val, nil
+}
+
+// parseAlias resolves a `*name` alias to the value registered for it by a
+// previous anchor
+func (p *Parser) parseAlias() (yamlVal, error) {
+	name := p.currToken.Value
+	val, ok := p.anchors[name]
+	if !ok {
+		return nil, newParseError(fmt.Sprintf("%s: %s", ErrUndefinedAlias.Error(), name), p.getPos())
+	}
+	return val, nil
+}
+
+// skipFlowSpace advances past SPACE and NEWLINE tokens. Inside a flow
+// collection, whitespace and line breaks between items are not
+// significant, so the block-style indentation rules are suspended.
+func (p *Parser) skipFlowSpace() {
+	for p.currToken.Type == SPACE || p.currToken.Type == NEWLINE {
+		p.NextToken()
+	}
+}
+
+// parseFlowValue parses a single value inside a flow collection
+// (`[...]`/`{...}`). Unlike parseValue it never has to deal with
+// indentation, since flow context suspends the block rules.
+func (p *Parser) parseFlowValue() (yamlVal, error) {
+	p.skipFlowSpace()
+	switch p.currToken.Type {
+	case STRING:
+		return yamlString(p.currToken.Value), nil
+	case INT_NUMBER:
+		num, err := strconv.Atoi(p.currToken.Value)
+		if err != nil {
+			return nil, newParseError("expected a number", p.getPos())
+		}
+		return yamlInt(num), nil
+	case FLOAT_NUMBER:
+		num, err := strconv.ParseFloat(p.currToken.Value, 64)
+		if err != nil {
+			return nil, newParseError("expected a number", p.getPos())
+		}
+		return yamlFloat(num), nil
+	case BOOLEAN:
+		b, err := strconv.ParseBool(p.currToken.Value)
+		if err != nil {
+			return nil, newParseError("expected a boolean", p.getPos())
+		}
+		return yamlBool(b), nil
+	case NULL:
+		return nil, nil
+	case LEFT_SQUARE_BRACKET:
+		return p.parseFlowSequence()
+	case LEFT_CURLY_BRACE:
+		return p.parseFlowMapping()
+	case ANCHOR:
+		return p.parseAnchor()
+	case ALIAS:
+		return p.parseAlias()
+	default:
+		return nil, newParseError("expected value in flow collection", p.getPos())
+	}
+}
+
+// parseFlowSequence parses a flow-style sequence, e.g. `[80, 443]`.
+// The current token must be LEFT_SQUARE_BRACKET. It returns with the
+// current token left at the closing RIGHT_SQUARE_BRACKET, matching the
+// convention of parseValue leaving scalar tokens unconsumed.
+func (p *Parser) parseFlowSequence() (yamlVal, error) {
+	p.NextToken() // consume '['
+	p.skipFlowSpace()
+
+	var list []yamlVal
+	if p.currToken.Type == RIGHT_SQUARE_BRACKET {
+		return yamlArray(list), nil
+	}
+
+	for {
+		val, err := p.parseFlowValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, val)
+
+		p.NextToken()
+		p.skipFlowSpace()
+		if p.currToken.Type == COMMA {
+			p.NextToken()
+			p.skipFlowSpace()
+			continue
+		}
+		if p.currToken.Type == RIGHT_SQUARE_BRACKET {
+			return yamlArray(list), nil
+		}
+		return nil, newParseError("expected ',' or ']' in flow sequence", p.getPos())
+	}
+}
+
+// parseFlowMapping parses a flow-style mapping, e.g. `{app: web, tier: frontend}`.
+// The current token must be LEFT_CURLY_BRACE. It returns with the current
+// token left at the closing RIGHT_CURLY_BRACE, matching the convention of
+// parseValue leaving scalar tokens unconsumed.
+func (p *Parser) parseFlowMapping() (yamlVal, error) {
+	obj := YAMLObj{keys: make(map[string]struct{})}
+
+	p.NextToken() // consume '{'
+	p.skipFlowSpace()
+
+	if p.currToken.Type == RIGHT_CURLY_BRACE {
+		return obj, nil
+	}
+
+	for {
+		if p.currToken.Type != STRING {
+			return nil, newParseError("expected string for key in flow mapping", p.getPos())
+		}
+		key := p.currToken.Value
+		p.NextToken()
+		p.skipFlowSpace()
+
+		if err := p.expect(COLON, "expected ':' after key declaration"); err != nil {
+			return nil, err
+		}
+		p.NextToken()
+		p.skipFlowSpace()
+
+		val, err := p.parseFlowValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := obj.append(KeyValue{Key: key, Value: val}); err != nil {
+			return nil, newParseError(err.Error(), p.getPos())
+		}
+
+		p.NextToken()
+		p.skipFlowSpace()
+		if p.currToken.Type == COMMA {
+			p.NextToken()
+			p.skipFlowSpace()
+			continue
+		}
+		if p.currToken.Type == RIGHT_CURLY_BRACE {
+			return obj, nil
+		}
+		return nil, newParseError("expected ',' or '}' in flow mapping", p.getPos())
+	}
+}
+
 // Handle SPACE token to track indentation
 func (p *Parser) handleSpace() (yamlVal, error) {
 	spaceCount := p.getIndentationLevel()
@@ -207,7 +563,7 @@ func (p *Parser) parseIndentedValue() (yamlVal, error) {
 	case HYPHEN: // Could indicate a YAML list
 		return p.parseList()
 	case STRING, COLON: // Could indicate a map (key-value pairs)
-		return p.Parse()
+		return p.parseMapping()
 	default:
 		return nil, newParseError(fmt.Sprintf("expected list or map after indentation, got: %s", p.currToken.Type.String()), p.getPos())
 	}
@@ -225,22 +581,31 @@ func (p *Parser) parseList() (yamlVal, error) {
 		}
 		list = append(list, item)
 
-		// get to the next line
+		// get to the end of the line, in case anything trails the value
 		for p.currToken.Type != NEWLINE {
-			if p.currToken.Type == EOF {
+			if p.currToken.Type == EOF || p.currToken.Type == DOC_START || p.currToken.Type == DOC_END {
 				break
 			}
 			p.NextToken()
 		}
 
+		if p.currToken.Type == EOF || p.currToken.Type == DOC_START || p.currToken.Type == DOC_END {
+			// nothing left to consume; let the caller see EOF/the marker
+			break
+		}
+		p.NextToken() // consume the newline, landing on the next line
+
+		if p.currToken.Type == EOF || p.currToken.Type == DOC_START || p.currToken.Type == DOC_END {
+			break
+		}
+
 		if p.currentIndentation > p.getIndentationLevel() {
 			break
 		}
 
-		if p.currToken.Type == SPACE && p.peekToken().Type != HYPHEN {
+		if p.currToken.Type != HYPHEN {
 			return nil, newParseError("list must have '-' character", p.getPos())
 		}
-		p.NextToken()
 	}
 	return yamlArray(list), nil
 }
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// lookupString returns the string value of key in obj's top-level pairs,
+// failing the test if it is missing or not a yamlString.
+func lookupString(t *testing.T, obj YAMLObj, key string) string {
+	t.Helper()
+	for _, pair := range obj.pairs {
+		if pair.Key == key {
+			s, ok := pair.Value.(yamlString)
+			if !ok {
+				t.Fatalf("key %q: expected yamlString, got %T", key, pair.Value)
+			}
+			return string(s)
+		}
+	}
+	t.Fatalf("key %q not found in %v", key, obj)
+	return ""
+}
+
+// TestBlockScalarFolding checks that folded ("> ") scalars fold single line
+// breaks to a space and preserve the number of blank lines as the same
+// number of newlines, rather than inflating each blank-line run by one.
+func TestBlockScalarFolding(t *testing.T) {
+	input := []byte("summary: >\n  one\n  two\n\n  three\n\n\n  four\n")
+
+	parser := NewParser(input)
+	obj, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	got := lookupString(t, obj, "summary")
+	want := "one two\nthree\n\nfour\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// TestBlockScalarChomping checks the "-" (strip) and "+" (keep) chomping
+// indicators, as well as an explicit indentation digit in the header.
+func TestBlockScalarChomping(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "clip (default)",
+			input: "body: |\n  line one\n  line two\n\n\n",
+			want:  "line one\nline two\n",
+		},
+		{
+			name:  "strip",
+			input: "body: |-\n  line one\n  line two\n\n\n",
+			want:  "line one\nline two",
+		},
+		{
+			name:  "keep",
+			input: "body: |+\n  line one\n  line two\n\n\n",
+			want:  "line one\nline two\n\n\n",
+		},
+		{
+			name:  "explicit indent",
+			input: "body: |2\n    line one\nother: value\n",
+			want:  "  line one\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser([]byte(tt.input))
+			obj, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			got := lookupString(t, obj, "body")
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestBlockList checks that a multi-item indented block list (e.g. under a
+// map key) is parsed in full, not just its first item.
+func TestBlockList(t *testing.T) {
+	parser := NewParser([]byte("items:\n  - a\n  - b\n  - c\n"))
+	obj, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for _, pair := range obj.pairs {
+		if pair.Key != "items" {
+			continue
+		}
+		arr, ok := pair.Value.(yamlArray)
+		if !ok {
+			t.Fatalf("expected yamlArray, got %T", pair.Value)
+		}
+		want := []string{"a", "b", "c"}
+		if len(arr) != len(want) {
+			t.Fatalf("expected %d items, got %d: %v", len(want), len(arr), arr)
+		}
+		for i, v := range arr {
+			if string(v.(yamlString)) != want[i] {
+				t.Errorf("item %d: expected %q, got %q", i, want[i], v)
+			}
+		}
+		return
+	}
+	t.Fatalf("key %q not found in %v", "items", obj)
+}
+
+// TestMultiDocWithNestedStructures checks that a document boundary following
+// a nested block mapping or list is recognized rather than being swallowed
+// into the current document (or corrupting the scan for the next one).
+func TestMultiDocWithNestedStructures(t *testing.T) {
+	t.Run("nested mapping", func(t *testing.T) {
+		parser := NewParser([]byte("outer:\n  inner: 1\n---\nflat: 2\n"))
+		docs, err := parser.All()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("expected 2 documents, got %d: %v", len(docs), docs)
+		}
+		if len(docs[0].pairs) != 1 || docs[0].pairs[0].Key != "outer" {
+			t.Errorf("expected doc 0 to only contain 'outer', got %v", docs[0])
+		}
+		if _, ok := docs[1].keys["flat"]; !ok {
+			t.Errorf("expected doc 1 to contain 'flat', got %v", docs[1])
+		}
+	})
+
+	t.Run("nested list", func(t *testing.T) {
+		parser := NewParser([]byte("items:\n  - a\n  - b\n---\nflat: 2\n"))
+		docs, err := parser.All()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("expected 2 documents, got %d: %v", len(docs), docs)
+		}
+		arr, ok := docs[0].pairs[0].Value.(yamlArray)
+		if !ok || len(arr) != 2 {
+			t.Fatalf("expected doc 0's 'items' to be a 2-element list, got %v", docs[0])
+		}
+		if _, ok := docs[1].keys["flat"]; !ok {
+			t.Errorf("expected doc 1 to contain 'flat', got %v", docs[1])
+		}
+	})
+}
+
+// TestPlainScalarWithCommaOutsideFlowContext checks that a plain scalar
+// containing a comma or brace parses as ordinary block-style content, since
+// flow indicators only terminate a scalar inside a flow collection.
+func TestPlainScalarWithCommaOutsideFlowContext(t *testing.T) {
+	parser := NewParser([]byte("desc: hello, world\n"))
+	obj, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	got := lookupString(t, obj, "desc")
+	want := "hello, world"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// TestAnchorsDoNotLeakAcrossDocuments checks that an anchor defined in one
+// document of a stream is not visible to an alias in a later document, since
+// YAML scopes anchors to a single document.
+func TestAnchorsDoNotLeakAcrossDocuments(t *testing.T) {
+	parser := NewParser([]byte("default: &d 1\n---\nother: *d\n"))
+
+	_, err := parser.Next()
+	if err != nil {
+		t.Fatalf("expected no error parsing the first document, got: %v", err)
+	}
+
+	_, err = parser.Next()
+	if err == nil || !strings.Contains(err.Error(), ErrUndefinedAlias.Error()) {
+		t.Fatalf("expected %v parsing the second document, got: %v", ErrUndefinedAlias, err)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// Pos represents a position within a yaml input: the file it came from
+// (when known) and the 1-indexed line/column of a byte in that file.
+type Pos struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+// InitPos is the position of the very first byte of an input
+var InitPos = Pos{Line: 1, Column: 1}
+
+func (p Pos) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+)
+
+// yamlFieldName returns the yaml key a struct field should be (de)serialized
+// under, whether it should be omitted when empty, and whether it should be
+// skipped entirely. It honors a `yaml:"name,omitempty"` struct tag, falling
+// back to the lowercased field name when no tag is present.
+func yamlFieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+	if tag == "" {
+		return strings.ToLower(field.Name), false, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
@@ -9,23 +9,32 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[INVALID-0]
-	_ = x[INDENT-1]
-	_ = x[DEDENT-2]
-	_ = x[COLON-3]
-	_ = x[HYPHEN-4]
-	_ = x[STRING-5]
-	_ = x[FLOAT_NUMBER-6]
-	_ = x[INT_NUMBER-7]
-	_ = x[BOOLEAN-8]
-	_ = x[NULL-9]
-	_ = x[COMMENT-10]
-	_ = x[NEWLINE-11]
-	_ = x[EOF-12]
+	_ = x[SPACE-1]
+	_ = x[COLON-2]
+	_ = x[HYPHEN-3]
+	_ = x[LEFT_SQUARE_BRACKET-4]
+	_ = x[RIGHT_SQUARE_BRACKET-5]
+	_ = x[STRING-6]
+	_ = x[FLOAT_NUMBER-7]
+	_ = x[INT_NUMBER-8]
+	_ = x[BOOLEAN-9]
+	_ = x[NULL-10]
+	_ = x[COMMENT-11]
+	_ = x[NEWLINE-12]
+	_ = x[EOF-13]
+	_ = x[LEFT_CURLY_BRACE-14]
+	_ = x[RIGHT_CURLY_BRACE-15]
+	_ = x[COMMA-16]
+	_ = x[ANCHOR-17]
+	_ = x[ALIAS-18]
+	_ = x[BLOCK_SCALAR_HEADER-19]
+	_ = x[DOC_START-20]
+	_ = x[DOC_END-21]
 }
 
-const _tokenType_name = "INVALIDINDENTDEDENTCOLONHYPHENSTRINGFLOAT_NUMBERINT_NUMBERBOOLEANNULLCOMMENTNEWLINEEOF"
+const _tokenType_name = "INVALIDSPACECOLONHYPHENLEFT_SQUARE_BRACKETRIGHT_SQUARE_BRACKETSTRINGFLOAT_NUMBERINT_NUMBERBOOLEANNULLCOMMENTNEWLINEEOFLEFT_CURLY_BRACERIGHT_CURLY_BRACECOMMAANCHORALIASBLOCK_SCALAR_HEADERDOC_STARTDOC_END"
 
-var _tokenType_index = [...]uint8{0, 7, 13, 19, 24, 30, 36, 48, 58, 65, 69, 76, 83, 86}
+var _tokenType_index = [...]uint8{0, 7, 12, 17, 23, 42, 62, 68, 80, 90, 97, 101, 108, 115, 118, 134, 151, 156, 162, 167, 186, 195, 202}
 
 func (i tokenType) String() string {
 	if i < 0 || i >= tokenType(len(_tokenType_index)-1) {
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrUnsupportedType     = errors.New("yaml: unsupported type")
+	errUnmarshalNonPointer = errors.New("yaml: Unmarshal requires a non-nil pointer")
+)
+
+// Unmarshaler is implemented by types that know how to decode their own
+// yaml representation. When a destination reached during Unmarshal
+// implements it, decoding is delegated to UnmarshalYAML instead of the
+// reflection-based path.
+type Unmarshaler interface {
+	UnmarshalYAML(data []byte) error
+}
+
+// Unmarshal parses yaml data and stores the result in the value pointed to
+// by v, binding it to a Go value via reflection. v must be a non-nil
+// pointer.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errUnmarshalNonPointer
+	}
+
+	obj, err := NewParser(data).Parse()
+	if err != nil {
+		return err
+	}
+
+	return decodeValue(obj, rv.Elem())
+}
+
+// indirect walks dst through any pointer levels, allocating as it goes, the
+// way kubernetes-sigs/yaml's indirect helper does, so the returned value is
+// addressable and settable. If it finds a type implementing Unmarshaler
+// along the way, it returns that instead so the caller can delegate to it.
+// When decodingNull is true, it stops at the last pointer level rather than
+// allocating one more, so the caller can set it to nil.
+func indirect(dst reflect.Value, decodingNull bool) (Unmarshaler, reflect.Value) {
+	for dst.Kind() == reflect.Pointer {
+		if decodingNull && dst.Type().Elem().Kind() != reflect.Pointer {
+			break
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		if u, ok := dst.Interface().(Unmarshaler); ok {
+			return u, reflect.Value{}
+		}
+		dst = dst.Elem()
+	}
+	return nil, dst
+}
+
+// decodeValue assigns val into dst, descending through pointers and
+// interfaces and dispatching to the appropriate Go kind.
+func decodeValue(val yamlVal, dst reflect.Value) error {
+	isNull := val == nil
+
+	u, dst := indirect(dst, isNull)
+	if u != nil {
+		return u.UnmarshalYAML(yamlValueBytes(val))
+	}
+
+	if isNull {
+		if dst.Kind() == reflect.Pointer {
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(nativeValue(val)))
+		return nil
+	case reflect.String:
+		s, ok := val.(yamlString)
+		if !ok {
+			return fmt.Errorf("%w: expected a string for %s", ErrUnsupportedType, dst.Type())
+		}
+		dst.SetString(string(s))
+		return nil
+	case reflect.Bool:
+		b, ok := val.(yamlBool)
+		if !ok {
+			return fmt.Errorf("%w: expected a bool for %s", ErrUnsupportedType, dst.Type())
+		}
+		dst.SetBool(bool(b))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := val.(yamlInt)
+		if !ok {
+			return fmt.Errorf("%w: expected an int for %s", ErrUnsupportedType, dst.Type())
+		}
+		dst.SetInt(int64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch n := val.(type) {
+		case yamlFloat:
+			dst.SetFloat(float64(n))
+		case yamlInt:
+			dst.SetFloat(float64(n))
+		default:
+			return fmt.Errorf("%w: expected a number for %s", ErrUnsupportedType, dst.Type())
+		}
+		return nil
+	case reflect.Slice:
+		arr, ok := val.(yamlArray)
+		if !ok {
+			return fmt.Errorf("%w: expected a sequence for %s", ErrUnsupportedType, dst.Type())
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+		for i, item := range arr {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Map:
+		obj, ok := val.(YAMLObj)
+		if !ok {
+			return fmt.Errorf("%w: expected a mapping for %s", ErrUnsupportedType, dst.Type())
+		}
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: map key must be a string, got %s", ErrUnsupportedType, dst.Type().Key())
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(obj.pairs))
+		for _, pair := range obj.pairs {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(pair.Value, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(pair.Key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(m)
+		return nil
+	case reflect.Struct:
+		obj, ok := val.(YAMLObj)
+		if !ok {
+			return fmt.Errorf("%w: expected a mapping for %s", ErrUnsupportedType, dst.Type())
+		}
+		return decodeStruct(obj, dst)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, dst.Type())
+	}
+}
+
+// decodeStruct assigns each pair of obj into the matching field of dst,
+// matching fields by their yaml tag (or lowercased name). Keys with no
+// matching field are ignored.
+func decodeStruct(obj YAMLObj, dst reflect.Value) error {
+	t := dst.Type()
+	fieldByName := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, skip := yamlFieldName(t.Field(i))
+		if skip {
+			continue
+		}
+		fieldByName[name] = i
+	}
+
+	for _, pair := range obj.pairs {
+		i, ok := fieldByName[pair.Key]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(pair.Value, dst.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nativeValue converts a parsed yamlVal into the plain Go value (string,
+// int, float64, bool, []any, map[string]any, or nil) used to fill an `any`
+// destination.
+func nativeValue(val yamlVal) any {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case yamlString:
+		return string(v)
+	case yamlInt:
+		return int(v)
+	case yamlFloat:
+		return float64(v)
+	case yamlBool:
+		return bool(v)
+	case yamlArray:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = nativeValue(item)
+		}
+		return out
+	case YAMLObj:
+		out := make(map[string]any, len(v.pairs))
+		for _, pair := range v.pairs {
+			out[pair.Key] = nativeValue(pair.Value)
+		}
+		return out
+	default:
+		return v.Value()
+	}
+}
+
+// yamlValueBytes renders val back into yaml text so it can be handed to an
+// Unmarshaler. Scalars round-trip faithfully; composite values fall back to
+// their Go representation, since Unmarshaler implementations are expected
+// to only be used for scalar types (e.g. custom string/number formats).
+func yamlValueBytes(val yamlVal) []byte {
+	if val == nil {
+		return []byte("null\n")
+	}
+
+	if s, ok := val.(yamlString); ok {
+		return []byte(string(s) + "\n")
+	}
+	return []byte(fmt.Sprintf("%v\n", val.Value()))
+}
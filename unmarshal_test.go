@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+type marshalAddress struct {
+	City string `yaml:"city"`
+	Zip  string `yaml:"zip,omitempty"`
+}
+
+type marshalPerson struct {
+	Name    string         `yaml:"name"`
+	Age     int            `yaml:"age"`
+	Admin   bool           `yaml:"admin"`
+	Address marshalAddress `yaml:"address"`
+	Tags    []string       `yaml:"tags"`
+	Ignored string         `yaml:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := []byte("name: Alice\nage: 30\nadmin: true\naddress: {city: Porto, zip: zip-4000}\ntags: [a, b]\n")
+
+	var got marshalPerson
+	if err := Unmarshal(input, &got); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	want := marshalPerson{
+		Name:    "Alice",
+		Age:     30,
+		Admin:   true,
+		Address: marshalAddress{City: "Porto", Zip: "zip-4000"},
+		Tags:    []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestUnmarshalNonPointer(t *testing.T) {
+	var got marshalPerson
+	if err := Unmarshal([]byte("name: Alice\n"), got); err == nil {
+		t.Errorf("expected an error when passing a non-pointer, got nil")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	in := marshalPerson{
+		Name:    "Alice",
+		Age:     30,
+		Admin:   true,
+		Address: marshalAddress{City: "Porto"},
+		Tags:    []string{"a", "b"},
+	}
+
+	out, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var roundTripped marshalPerson
+	if err := Unmarshal(out, &roundTripped); err != nil {
+		t.Fatalf("expected marshaled output to be valid yaml, got error: %v\noutput:\n%s", err, out)
+	}
+	if !reflect.DeepEqual(roundTripped, in) {
+		t.Errorf("round trip mismatch: expected %+v, got %+v", in, roundTripped)
+	}
+}
+
+// TestMarshalRoundTripQuotedScalars checks that values Marshal quotes (since
+// left bare they'd change meaning, e.g. a numeric-looking string or one
+// containing a flow indicator) read back as their original string rather
+// than with the quote characters embedded in them.
+func TestMarshalRoundTripQuotedScalars(t *testing.T) {
+	in := marshalPerson{
+		Name:    "123",
+		Address: marshalAddress{City: "Porto, Portugal"},
+		Tags:    []string{"a", "b"},
+	}
+
+	out, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var roundTripped marshalPerson
+	if err := Unmarshal(out, &roundTripped); err != nil {
+		t.Fatalf("expected marshaled output to be valid yaml, got error: %v\noutput:\n%s", err, out)
+	}
+	if !reflect.DeepEqual(roundTripped, in) {
+		t.Errorf("round trip mismatch: expected %+v, got %+v", in, roundTripped)
+	}
+}